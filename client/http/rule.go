@@ -0,0 +1,152 @@
+// Copyright 2024 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/pingcap/errors"
+)
+
+// EnforcementScope identifies which subsystem a ScopedEnforcementAction
+// applies to. EnforcementScope/EnforcementActionType/ScopedEnforcementAction
+// deliberately duplicate pkg/schedule/placement's types of the same name
+// instead of importing them: this package is part of the standalone
+// `client` module, which must not depend on pkg/, the main module's server
+// code (see Rule's doc comment for the same reasoning applied to the rule
+// type itself).
+type EnforcementScope string
+
+// EnforcementActionType is the action taken for a given scope.
+type EnforcementActionType string
+
+// Known enforcement scopes and actions. Keep in sync with
+// pkg/schedule/placement.
+const (
+	ScheduleScope EnforcementScope = "schedule"
+	AuditScope    EnforcementScope = "audit"
+
+	ActionDeny  EnforcementActionType = "deny"
+	ActionWarn  EnforcementActionType = "warn"
+	ActionAllow EnforcementActionType = "allow"
+)
+
+var validEnforcementScopes = map[EnforcementScope]struct{}{
+	ScheduleScope: {},
+	AuditScope:    {},
+}
+
+// ScopedEnforcementAction binds an enforcement action to a single scope,
+// e.g. {Scope: "audit", Action: "warn"}.
+type ScopedEnforcementAction struct {
+	Scope  EnforcementScope      `json:"scope"`
+	Action EnforcementActionType `json:"action"`
+}
+
+func validateScopedActions(actions []ScopedEnforcementAction) error {
+	for _, a := range actions {
+		if _, ok := validEnforcementScopes[a.Scope]; !ok {
+			return errors.Errorf("unknown enforcement scope %q", a.Scope)
+		}
+	}
+	return nil
+}
+
+// GetPlacementRuleByGroupAndID fetches a single placement rule.
+func (c *client) GetPlacementRuleByGroupAndID(ctx context.Context, groupID, ruleID string) (*Rule, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		c.url(fmt.Sprintf("%s/%s/%s", RulesPath, url.PathEscape(groupID), url.PathEscape(ruleID))), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.cli.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("get placement rule %s/%s: unexpected status %s", groupID, ruleID, resp.Status)
+	}
+	var rule Rule
+	if err := json.NewDecoder(resp.Body).Decode(&rule); err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// SetPlacementRule creates or updates a single placement rule, including
+// its EnforcementActions.
+func (c *client) SetPlacementRule(ctx context.Context, rule *Rule) error {
+	if err := validateScopedActions(rule.EnforcementActions); err != nil {
+		return err
+	}
+	return c.postRule(ctx, RulesPath, rule)
+}
+
+// SetPlacementRuleInBatch creates or updates several placement rules in one
+// request, each carrying its own EnforcementActions.
+func (c *client) SetPlacementRuleInBatch(ctx context.Context, rules []*Rule) error {
+	for _, rule := range rules {
+		if err := validateScopedActions(rule.EnforcementActions); err != nil {
+			return err
+		}
+	}
+	return c.postRule(ctx, RulesPath+"/batch", rules)
+}
+
+func (c *client) postRule(ctx context.Context, path string, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url(path), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.cli.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("set placement rule: unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// SetPlacementRuleScopedAction sets the per-scope enforcement actions on an
+// existing placement rule, e.g. to dry-run a rule by setting all of its
+// scopes to "audit" before promoting it to "deny".
+func (c *client) SetPlacementRuleScopedAction(
+	ctx context.Context, groupID, ruleID string, actions []ScopedEnforcementAction,
+) error {
+	// SetPlacementRule validates actions too, but check here first so an
+	// unknown scope is rejected before we bother fetching the existing rule.
+	if err := validateScopedActions(actions); err != nil {
+		return err
+	}
+	rule, err := c.GetPlacementRuleByGroupAndID(ctx, groupID, ruleID)
+	if err != nil {
+		return err
+	}
+	rule.EnforcementActions = actions
+	return c.SetPlacementRule(ctx, rule)
+}
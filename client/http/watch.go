@@ -0,0 +1,137 @@
+// Copyright 2024 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/pingcap/errors"
+)
+
+// RuleEventKind is the kind of incremental change a RuleEvent describes.
+type RuleEventKind string
+
+// Known rule event kinds. The server currently only distinguishes
+// update (covering both create and update) from delete; RuleEventAdd is
+// reserved in case the server starts telling them apart. RuleEventError is
+// never sent by the server: watchRules emits it locally, as the last event
+// before closing the channel, when the SSE stream ends because of a read
+// error rather than a clean close.
+const (
+	RuleEventAdd    RuleEventKind = "add"
+	RuleEventUpdate RuleEventKind = "update"
+	RuleEventDelete RuleEventKind = "delete"
+	RuleEventError  RuleEventKind = "error"
+)
+
+// RuleEvent is a single incremental change streamed by
+// WatchPlacementRules/WatchRegionLabelRules. It is the wire shape the
+// server's watch endpoint (server/api.RegisterRuleWatchRoutes) writes as
+// SSE frames; it is deliberately not the same type as the server's
+// internal endpoint.RuleEvent, which is keyed by raw JSON string rather
+// than the parsed Rule/LabelRule object this type carries. Key identifies
+// which rule changed and is always set, including on Delete, where
+// Rule/LabelRule are nil — it's the only way to know which rule was
+// removed.
+type RuleEvent struct {
+	Type      RuleEventKind `json:"type"`
+	Key       string        `json:"key"`
+	Rule      *Rule         `json:"rule,omitempty"`
+	LabelRule *LabelRule    `json:"label_rule,omitempty"`
+	Revision  int64         `json:"revision"`
+	// Err is set only on a RuleEventError event (see RuleEventError); it is
+	// never populated from the wire.
+	Err error `json:"-"`
+}
+
+// watchPath builds the watch endpoint URI for path, optionally resuming
+// from a given etcd revision instead of doing a full reload.
+func watchPath(path string, fromRevision int64) string {
+	if fromRevision > 0 {
+		return fmt.Sprintf("%s/watch?from_revision=%d", path, fromRevision)
+	}
+	return fmt.Sprintf("%s/watch", path)
+}
+
+// WatchPlacementRules streams incremental placement rule changes, mirroring
+// the informer pattern used by Kubernetes-style controllers: callers drain
+// the returned channel instead of polling GetAllPlacementRuleBundles.
+func (c *client) WatchPlacementRules(ctx context.Context) (<-chan RuleEvent, error) {
+	return c.watchRules(ctx, RulesPath, 0)
+}
+
+// WatchRegionLabelRules streams incremental region label rule changes.
+func (c *client) WatchRegionLabelRules(ctx context.Context) (<-chan RuleEvent, error) {
+	return c.watchRules(ctx, RegionLabelRulesPath, 0)
+}
+
+// Resume reconnects a watch starting just after fromRevision, so a consumer
+// that lost its connection doesn't need to reload the full rule set.
+func (c *client) Resume(ctx context.Context, path string, fromRevision int64) (<-chan RuleEvent, error) {
+	return c.watchRules(ctx, path, fromRevision)
+}
+
+func (c *client) watchRules(ctx context.Context, path string, fromRevision int64) (<-chan RuleEvent, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url(watchPath(path, fromRevision)), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := c.cli.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, errors.Errorf("watch %s: unexpected status %s", path, resp.Status)
+	}
+
+	out := make(chan RuleEvent, 16)
+	go func() {
+		defer close(out)
+		defer resp.Body.Close()
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if !strings.HasPrefix(line, "data: ") {
+				continue
+			}
+			var ev RuleEvent
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &ev); err != nil {
+				continue
+			}
+			select {
+			case out <- ev:
+			case <-ctx.Done():
+				return
+			}
+		}
+		// scanner.Scan() also returns false on a clean EOF; Err distinguishes
+		// that from a dropped connection or a line over bufio.MaxScanTokenSize,
+		// either of which means events were lost, not just that the watch ended.
+		if err := scanner.Err(); err != nil {
+			select {
+			case out <- RuleEvent{Type: RuleEventError, Err: err}:
+			case <-ctx.Done():
+			}
+		}
+	}()
+	return out, nil
+}
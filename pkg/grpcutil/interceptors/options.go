@@ -0,0 +1,43 @@
+// Copyright 2024 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interceptors
+
+import "google.golang.org/grpc"
+
+// DefaultServerOptions returns the grpc.ServerOptions every PD gRPC server
+// (the API server, the TSO service and the scheduling service) should pass
+// to grpc.NewServer, wiring in panic recovery and the active-stream gauge.
+func DefaultServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.UnaryInterceptor(UnaryRecovery()),
+		grpc.StreamInterceptor(chainStreamInterceptors(StreamRecovery(), StreamCounter())),
+	}
+}
+
+// chainStreamInterceptors composes multiple stream interceptors into one,
+// since grpc.NewServer only accepts a single grpc.StreamInterceptor option.
+func chainStreamInterceptors(interceptors ...grpc.StreamServerInterceptor) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		chain := handler
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor := interceptors[i]
+			next := chain
+			chain = func(srv interface{}, ss grpc.ServerStream) error {
+				return interceptor(srv, ss, info, next)
+			}
+		}
+		return chain(srv, ss)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2024 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interceptors
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryRecoveryRecoversPanic(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/pdpb.PD/GetRegion"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		panic("boom")
+	}
+
+	resp, err := UnaryRecovery()(context.Background(), nil, info, handler)
+	if resp != nil {
+		t.Errorf("resp = %v, want nil", resp)
+	}
+	if status.Code(err) != codes.Internal {
+		t.Errorf("code = %v, want %v", status.Code(err), codes.Internal)
+	}
+}
+
+func TestUnaryRecoveryPassesThroughNormalResult(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/pdpb.PD/GetRegion"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+
+	resp, err := UnaryRecovery()(context.Background(), nil, info, handler)
+	if err != nil {
+		t.Errorf("err = %v, want nil", err)
+	}
+	if resp != "ok" {
+		t.Errorf("resp = %v, want %q", resp, "ok")
+	}
+}
+
+type fakeServerStream struct {
+	grpc.ServerStream
+}
+
+func TestStreamRecoveryRecoversPanic(t *testing.T) {
+	info := &grpc.StreamServerInfo{FullMethod: "/pdpb.PD/Watch"}
+	handler := func(srv interface{}, ss grpc.ServerStream) error {
+		panic("boom")
+	}
+
+	err := StreamRecovery()(nil, &fakeServerStream{}, info, handler)
+	if status.Code(err) != codes.Internal {
+		t.Errorf("code = %v, want %v", status.Code(err), codes.Internal)
+	}
+}
+
+func TestStreamRecoveryPassesThroughNormalResult(t *testing.T) {
+	info := &grpc.StreamServerInfo{FullMethod: "/pdpb.PD/Watch"}
+	handler := func(srv interface{}, ss grpc.ServerStream) error {
+		return nil
+	}
+
+	if err := StreamRecovery()(nil, &fakeServerStream{}, info, handler); err != nil {
+		t.Errorf("err = %v, want nil", err)
+	}
+}
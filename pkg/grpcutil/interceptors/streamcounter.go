@@ -0,0 +1,59 @@
+// Copyright 2024 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interceptors
+
+import (
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+)
+
+// activeStreams exports the number of currently open gRPC streams, labeled
+// by service and method, so client stream leaks show up as a steadily
+// growing gauge instead of requiring a heap dump to notice.
+var activeStreams = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: "pd",
+		Subsystem: "grpc",
+		Name:      "active_streams",
+		Help:      "Gauge of currently open gRPC streams, by service and method.",
+	}, []string{"service", "method"})
+
+func init() {
+	prometheus.MustRegister(activeStreams)
+}
+
+// StreamCounter returns a stream interceptor that tracks the
+// pd_grpc_active_streams gauge for the lifetime of each stream.
+func StreamCounter() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		service, method := splitMethod(info.FullMethod)
+		gauge := activeStreams.WithLabelValues(service, method)
+		gauge.Inc()
+		defer gauge.Dec()
+		return handler(srv, ss)
+	}
+}
+
+// splitMethod splits a gRPC FullMethod of the form "/service/method" into
+// its service and method parts.
+func splitMethod(fullMethod string) (service, method string) {
+	parts := strings.SplitN(strings.TrimPrefix(fullMethod, "/"), "/", 2)
+	if len(parts) != 2 {
+		return fullMethod, ""
+	}
+	return parts[0], parts[1]
+}
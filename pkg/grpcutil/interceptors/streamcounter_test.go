@@ -0,0 +1,34 @@
+// Copyright 2024 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interceptors
+
+import "testing"
+
+func TestSplitMethod(t *testing.T) {
+	cases := []struct {
+		full    string
+		service string
+		method  string
+	}{
+		{"/pdpb.PD/GetRegion", "pdpb.PD", "GetRegion"},
+		{"not-a-method", "not-a-method", ""},
+	}
+	for _, c := range cases {
+		service, method := splitMethod(c.full)
+		if service != c.service || method != c.method {
+			t.Errorf("splitMethod(%q) = (%q, %q), want (%q, %q)", c.full, service, method, c.service, c.method)
+		}
+	}
+}
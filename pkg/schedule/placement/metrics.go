@@ -0,0 +1,41 @@
+// Copyright 2024 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// ruleEnforcementCounter tracks how many times a rule's scoped enforcement
+// action has been triggered, broken down by scope and action so an "audit"
+// dry-run rule can be compared against the "schedule" one before promotion.
+var ruleEnforcementCounter = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "pd",
+		Subsystem: "placement",
+		Name:      "rule_enforcement_triggered_total",
+		Help:      "Counter of placement rule enforcement actions triggered, by scope and action.",
+	}, []string{"group_id", "rule_id", "scope", "action"})
+
+func init() {
+	prometheus.MustRegister(ruleEnforcementCounter)
+}
+
+// ObserveEnforcement records that the given rule's action for scope was
+// triggered. Callers in the balancer, scatter and merge checker should call
+// this whenever they consult a rule's scoped enforcement action, regardless
+// of whether the action ends up blocking the decision.
+func ObserveEnforcement(r *Rule, scope EnforcementScope) {
+	action := r.ActionForScope(scope)
+	ruleEnforcementCounter.WithLabelValues(r.GroupID, r.ID, string(scope), string(action)).Inc()
+}
@@ -0,0 +1,37 @@
+// Copyright 2024 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObserveEnforcementIncrementsByScopeAndAction(t *testing.T) {
+	re := require.New(t)
+	ruleEnforcementCounter.Reset()
+
+	r := &Rule{GroupID: "g", ID: "1"}
+	ObserveEnforcement(r, ScheduleScope)
+	ObserveEnforcement(r, ScheduleScope)
+	ObserveEnforcement(r, AuditScope)
+
+	re.Equal(float64(2), testutil.ToFloat64(
+		ruleEnforcementCounter.WithLabelValues("g", "1", string(ScheduleScope), string(ActionDeny))))
+	re.Equal(float64(1), testutil.ToFloat64(
+		ruleEnforcementCounter.WithLabelValues("g", "1", string(AuditScope), string(ActionAllow))))
+}
@@ -0,0 +1,144 @@
+// Copyright 2024 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	"encoding/json"
+
+	"github.com/pingcap/errors"
+)
+
+// PeerRoleType is the expected peer type of the placement rule.
+type PeerRoleType string
+
+const (
+	// Voter can either match a leader peer or follower peer.
+	Voter PeerRoleType = "voter"
+	// Leader matches a leader.
+	Leader PeerRoleType = "leader"
+	// Follower matches a follower.
+	Follower PeerRoleType = "follower"
+	// Learner matches a learner.
+	Learner PeerRoleType = "learner"
+)
+
+// EnforcementScope identifies which subsystem a ScopedEnforcementAction applies to.
+type EnforcementScope string
+
+const (
+	// ScheduleScope is consulted by the scheduler, scatter and merge checker to
+	// decide whether a placement decision is allowed.
+	ScheduleScope EnforcementScope = "schedule"
+	// AuditScope only emits metrics and PD log records; it never blocks a decision.
+	AuditScope EnforcementScope = "audit"
+)
+
+// EnforcementActionType is the action taken for a given scope.
+type EnforcementActionType string
+
+const (
+	// ActionDeny blocks the placement decision in the given scope.
+	ActionDeny EnforcementActionType = "deny"
+	// ActionWarn allows the placement decision but records a warning.
+	ActionWarn EnforcementActionType = "warn"
+	// ActionAllow allows the placement decision silently.
+	ActionAllow EnforcementActionType = "allow"
+)
+
+// validScopes is the set of scopes a ScopedEnforcementAction may reference.
+var validScopes = map[EnforcementScope]struct{}{
+	ScheduleScope: {},
+	AuditScope:    {},
+}
+
+// ScopedEnforcementAction binds an enforcement action to a single subsystem
+// scope, e.g. {Scope: "schedule", Action: "deny"}.
+type ScopedEnforcementAction struct {
+	Scope  EnforcementScope      `json:"scope"`
+	Action EnforcementActionType `json:"action"`
+}
+
+// Validate checks that the scope is one of the known values.
+func (a ScopedEnforcementAction) Validate() error {
+	if _, ok := validScopes[a.Scope]; !ok {
+		return errors.Errorf("unknown enforcement scope %q", a.Scope)
+	}
+	return nil
+}
+
+// Rule is the placement rule that can be checked against a region.
+type Rule struct {
+	GroupID         string       `json:"group_id"`
+	ID              string       `json:"id"`
+	Index           int          `json:"index,omitempty"`
+	Override        bool         `json:"override,omitempty"`
+	StartKeyHex     string       `json:"start_key"`
+	EndKeyHex       string       `json:"end_key"`
+	Role            PeerRoleType `json:"role"`
+	IsWitness       bool         `json:"is_witness"`
+	Count           int          `json:"count"`
+	LocationLabels  []string     `json:"location_labels,omitempty"`
+	IsolationLevel  string       `json:"isolation_level,omitempty"`
+	Version         uint64       `json:"version,omitempty"`
+	CreateTimestamp uint64       `json:"create_timestamp,omitempty"`
+	// EnforcementActions lists the per-scope enforcement to apply for this
+	// rule. An empty list is treated as a single {schedule, deny} action for
+	// backward compatibility with rules persisted before this field existed.
+	EnforcementActions []ScopedEnforcementAction `json:"enforcement_actions,omitempty"`
+}
+
+// ruleAlias avoids infinite recursion in UnmarshalJSON.
+type ruleAlias Rule
+
+// legacyEnforcementActions is what a Rule with no EnforcementActions at all
+// is treated as having — whether because it predates the field (loaded via
+// UnmarshalJSON) or because it was constructed directly in Go and never
+// round-tripped through JSON. Keeping both paths agree on the same default
+// means a Rule built in-process behaves identically to the same rule loaded
+// from storage.
+var legacyEnforcementActions = []ScopedEnforcementAction{{Scope: ScheduleScope, Action: ActionDeny}}
+
+// UnmarshalJSON implements json.Unmarshaler. Rules saved before
+// EnforcementActions existed are treated as legacyEnforcementActions, so old
+// rules keep their previous (blocking) behavior.
+func (r *Rule) UnmarshalJSON(data []byte) error {
+	alias := (*ruleAlias)(r)
+	if err := json.Unmarshal(data, alias); err != nil {
+		return err
+	}
+	if len(r.EnforcementActions) == 0 {
+		r.EnforcementActions = legacyEnforcementActions
+	}
+	return nil
+}
+
+// ActionForScope returns the enforcement action configured for the given
+// scope. A Rule with no EnforcementActions at all is treated as
+// legacyEnforcementActions (see its doc comment), so this agrees with
+// UnmarshalJSON regardless of whether the Rule was loaded from storage or
+// built directly in Go; a scope with no entry in those actions defaults to
+// ActionAllow.
+func (r *Rule) ActionForScope(scope EnforcementScope) EnforcementActionType {
+	actions := r.EnforcementActions
+	if len(actions) == 0 {
+		actions = legacyEnforcementActions
+	}
+	for _, a := range actions {
+		if a.Scope == scope {
+			return a.Action
+		}
+	}
+	return ActionAllow
+}
@@ -0,0 +1,71 @@
+// Copyright 2024 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuleUnmarshalJSONDefaultsLegacyEnforcement(t *testing.T) {
+	re := require.New(t)
+	var r Rule
+	re.NoError(json.Unmarshal([]byte(`{"group_id":"g","id":"1"}`), &r))
+	re.Equal(legacyEnforcementActions, r.EnforcementActions)
+}
+
+func TestRuleUnmarshalJSONKeepsExplicitEnforcement(t *testing.T) {
+	re := require.New(t)
+	var r Rule
+	data := `{"group_id":"g","id":"1","enforcement_actions":[{"scope":"audit","action":"warn"}]}`
+	re.NoError(json.Unmarshal([]byte(data), &r))
+	re.Equal([]ScopedEnforcementAction{{Scope: AuditScope, Action: ActionWarn}}, r.EnforcementActions)
+}
+
+func TestActionForScopeMatchesUnmarshalDefault(t *testing.T) {
+	re := require.New(t)
+
+	// A Rule built directly in Go, never round-tripped through JSON, must
+	// see the same default as one loaded from storage with no
+	// enforcement_actions at all.
+	bare := &Rule{GroupID: "g", ID: "1"}
+	re.Equal(ActionDeny, bare.ActionForScope(ScheduleScope))
+	re.Equal(ActionAllow, bare.ActionForScope(AuditScope))
+
+	var loaded Rule
+	re.NoError(json.Unmarshal([]byte(`{"group_id":"g","id":"1"}`), &loaded))
+	re.Equal(bare.ActionForScope(ScheduleScope), loaded.ActionForScope(ScheduleScope))
+	re.Equal(bare.ActionForScope(AuditScope), loaded.ActionForScope(AuditScope))
+}
+
+func TestActionForScopeUnmentionedScope(t *testing.T) {
+	re := require.New(t)
+	r := &Rule{
+		GroupID:            "g",
+		ID:                 "1",
+		EnforcementActions: []ScopedEnforcementAction{{Scope: ScheduleScope, Action: ActionWarn}},
+	}
+	re.Equal(ActionWarn, r.ActionForScope(ScheduleScope))
+	re.Equal(ActionAllow, r.ActionForScope(AuditScope))
+}
+
+func TestScopedEnforcementActionValidate(t *testing.T) {
+	re := require.New(t)
+	re.NoError(ScopedEnforcementAction{Scope: ScheduleScope, Action: ActionDeny}.Validate())
+	re.NoError(ScopedEnforcementAction{Scope: AuditScope, Action: ActionWarn}.Validate())
+	re.Error(ScopedEnforcementAction{Scope: "bogus", Action: ActionDeny}.Validate())
+}
@@ -0,0 +1,88 @@
+// Copyright 2024 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package endpoint
+
+import (
+	"context"
+
+	"github.com/pingcap/errors"
+)
+
+// RuleEventType describes the kind of change that happened to a rule key.
+type RuleEventType int
+
+const (
+	// RuleEventPut is emitted when a rule key is created or updated.
+	RuleEventPut RuleEventType = iota
+	// RuleEventDelete is emitted when a rule key is removed.
+	RuleEventDelete
+)
+
+// RuleEvent is a single out-of-band change observed on a rule backend.
+// Revision is a backend-specific, monotonically increasing sequence number
+// (the etcd mod revision, the Consul ModifyIndex, or a local counter for the
+// file backend) that callers can pass back into Watch to resume without
+// replaying events they've already seen.
+type RuleEvent struct {
+	Type     RuleEventType
+	Key      string
+	Value    string
+	Revision int64
+}
+
+// RuleBackend abstracts the key-value store that rules are persisted to, so
+// the etcd-backed StorageEndpoint is just one implementation among others
+// (e.g. a local file/BoltDB store for single-node dev clusters, or Consul
+// for deployments that already run it).
+type RuleBackend interface {
+	Load(key string) (string, error)
+	LoadRange(key, endKey string, limit int) (keys, values []string, err error)
+	Save(key, value string) error
+	Remove(key string) error
+	// Watch streams RuleEvents for every key under prefix until ctx is
+	// canceled or the returned channel's producer stops. fromRevision, if
+	// greater than 0, replays events after that revision before switching to
+	// live updates, so a reconnecting caller doesn't need a full reload.
+	Watch(ctx context.Context, prefix string, fromRevision int64) (<-chan RuleEvent, error)
+}
+
+// RuleBackendKind identifies which RuleBackend implementation to construct.
+type RuleBackendKind string
+
+// Supported rule storage backends. Keep in sync with the
+// `[replication] rule-storage-backend` config key.
+const (
+	RuleBackendEtcd   RuleBackendKind = "etcd"
+	RuleBackendFile   RuleBackendKind = "file"
+	RuleBackendConsul RuleBackendKind = "consul"
+)
+
+// NewRuleBackend constructs the RuleBackend for the given kind. opts is
+// backend-specific: the BoltDB database file path for RuleBackendFile, a Consul address
+// for RuleBackendConsul, and is ignored for RuleBackendEtcd (the etcd
+// backend is constructed by wrapping an existing StorageEndpoint instead,
+// via NewEtcdRuleBackend).
+func NewRuleBackend(kind RuleBackendKind, opts string) (RuleBackend, error) {
+	switch kind {
+	case RuleBackendFile:
+		return newFileRuleBackend(opts)
+	case RuleBackendConsul:
+		return newConsulRuleBackend(opts)
+	case RuleBackendEtcd:
+		return nil, errors.Errorf("the etcd rule backend wraps an existing StorageEndpoint; use NewEtcdRuleBackend instead")
+	default:
+		return nil, errors.Errorf("unknown rule storage backend %q", kind)
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2024 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package endpoint
+
+import (
+	"context"
+	"strings"
+
+	consulapi "github.com/hashicorp/consul/api"
+)
+
+// consulRuleBackend persists rules to a Consul KV store, for deployments
+// that already run Consul and don't want to stand up etcd just for PD.
+type consulRuleBackend struct {
+	kv *consulapi.KV
+}
+
+func newConsulRuleBackend(addr string) (RuleBackend, error) {
+	cfg := consulapi.DefaultConfig()
+	if addr != "" {
+		cfg.Address = addr
+	}
+	client, err := consulapi.NewClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &consulRuleBackend{kv: client.KV()}, nil
+}
+
+// Load implements RuleBackend.
+func (b *consulRuleBackend) Load(key string) (string, error) {
+	pair, _, err := b.kv.Get(key, nil)
+	if err != nil {
+		return "", err
+	}
+	if pair == nil {
+		return "", nil
+	}
+	return string(pair.Value), nil
+}
+
+// LoadRange implements RuleBackend, returning keys in [key, endKey). Consul
+// can only list by prefix, so it lists the prefix key and endKey share and
+// skips what falls before key. With an empty endKey, key is the prefix.
+func (b *consulRuleBackend) LoadRange(key, endKey string, limit int) (keys, values []string, err error) {
+	listPrefix := key
+	if endKey != "" {
+		n := 0
+		for n < len(key) && n < len(endKey) && key[n] == endKey[n] {
+			n++
+		}
+		listPrefix = key[:n]
+	}
+	pairs, _, err := b.kv.List(listPrefix, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, pair := range pairs {
+		if pair.Key < key {
+			continue
+		}
+		if endKey != "" && pair.Key >= endKey {
+			break
+		}
+		keys = append(keys, pair.Key)
+		values = append(values, string(pair.Value))
+		if limit > 0 && len(keys) >= limit {
+			break
+		}
+	}
+	return keys, values, nil
+}
+
+// Save implements RuleBackend.
+func (b *consulRuleBackend) Save(key, value string) error {
+	_, err := b.kv.Put(&consulapi.KVPair{Key: key, Value: []byte(value)}, nil)
+	return err
+}
+
+// Remove implements RuleBackend.
+func (b *consulRuleBackend) Remove(key string) error {
+	_, err := b.kv.Delete(key, nil)
+	return err
+}
+
+// Watch implements RuleBackend by long-polling Consul's blocking queries on
+// prefix and diffing successive ModifyIndex values. fromRevision, if set, is
+// used as the initial Consul WaitIndex, so the first poll returns once the
+// prefix has changed after it. That first poll emits a Put for every key
+// currently under prefix, including keys unchanged since fromRevision (and,
+// with fromRevision 0, the whole current set); keys deleted before it are
+// not reported. The poll loop exits as soon as ctx is canceled or a List
+// call fails.
+func (b *consulRuleBackend) Watch(ctx context.Context, prefix string, fromRevision int64) (<-chan RuleEvent, error) {
+	out := make(chan RuleEvent, 16)
+	go b.pollLoop(ctx, prefix, uint64(fromRevision), out)
+	return out, nil
+}
+
+func (b *consulRuleBackend) pollLoop(ctx context.Context, prefix string, waitIndex uint64, out chan<- RuleEvent) {
+	defer close(out)
+	seen := make(map[string]uint64)
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+		pairs, meta, err := b.kv.List(prefix, (&consulapi.QueryOptions{WaitIndex: waitIndex}).WithContext(ctx))
+		if err != nil {
+			return
+		}
+		waitIndex = meta.LastIndex
+		live := make(map[string]struct{}, len(pairs))
+		for _, pair := range pairs {
+			if !strings.HasPrefix(pair.Key, prefix) {
+				continue
+			}
+			live[pair.Key] = struct{}{}
+			if idx, ok := seen[pair.Key]; ok && idx == pair.ModifyIndex {
+				continue
+			}
+			seen[pair.Key] = pair.ModifyIndex
+			select {
+			case out <- RuleEvent{Type: RuleEventPut, Key: pair.Key, Value: string(pair.Value), Revision: int64(pair.ModifyIndex)}:
+			case <-ctx.Done():
+				return
+			}
+		}
+		for key := range seen {
+			if _, ok := live[key]; !ok {
+				delete(seen, key)
+				select {
+				case out <- RuleEvent{Type: RuleEventDelete, Key: key, Revision: int64(waitIndex)}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright 2024 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package endpoint
+
+import (
+	"context"
+
+	"go.etcd.io/etcd/clientv3"
+	"go.etcd.io/etcd/mvcc/mvccpb"
+)
+
+// etcdRuleBackend adapts the existing StorageEndpoint to the RuleBackend
+// interface, so etcd remains just one of several pluggable rule stores.
+type etcdRuleBackend struct {
+	*StorageEndpoint
+	client *clientv3.Client
+}
+
+// NewEtcdRuleBackend wraps se, reusing its existing etcd client for watches.
+// Prefer this over NewEtcdRuleBackendFromClient when a StorageEndpoint
+// already exists (e.g. in the server), since it reuses its Load/Save/Remove
+// rather than issuing separate clientv3 calls.
+func NewEtcdRuleBackend(se *StorageEndpoint, client *clientv3.Client) RuleBackend {
+	return &etcdRuleBackend{StorageEndpoint: se, client: client}
+}
+
+// Load implements RuleBackend.
+func (b *etcdRuleBackend) Load(key string) (string, error) {
+	return b.StorageEndpoint.Load(key)
+}
+
+// Save implements RuleBackend.
+func (b *etcdRuleBackend) Save(key, value string) error {
+	return b.StorageEndpoint.Save(key, value)
+}
+
+// Remove implements RuleBackend.
+func (b *etcdRuleBackend) Remove(key string) error {
+	return b.StorageEndpoint.Remove(key)
+}
+
+// Watch implements RuleBackend by forwarding the underlying etcd watch
+// channel for prefix. It stops and closes the returned channel as soon as
+// ctx is canceled.
+func (b *etcdRuleBackend) Watch(ctx context.Context, prefix string, fromRevision int64) (<-chan RuleEvent, error) {
+	return watchEtcdPrefix(ctx, b.client, prefix, fromRevision), nil
+}
+
+// etcdClientRuleBackend is a RuleBackend backed directly by a bare
+// *clientv3.Client, for callers that have an etcd client but no existing
+// StorageEndpoint to wrap — e.g. tools/pd-rule-migration, which needs to
+// talk to an etcd-backed cluster without running inside a PD server.
+type etcdClientRuleBackend struct {
+	client *clientv3.Client
+}
+
+// NewEtcdRuleBackendFromClient builds a RuleBackend backed directly by
+// client. See NewEtcdRuleBackend for the server-side alternative.
+func NewEtcdRuleBackendFromClient(client *clientv3.Client) RuleBackend {
+	return &etcdClientRuleBackend{client: client}
+}
+
+// Load implements RuleBackend.
+func (b *etcdClientRuleBackend) Load(key string) (string, error) {
+	resp, err := b.client.Get(context.Background(), key)
+	if err != nil {
+		return "", err
+	}
+	if len(resp.Kvs) == 0 {
+		return "", nil
+	}
+	return string(resp.Kvs[0].Value), nil
+}
+
+// LoadRange implements RuleBackend.
+func (b *etcdClientRuleBackend) LoadRange(key, endKey string, limit int) (keys, values []string, err error) {
+	opts := []clientv3.OpOption{clientv3.WithSort(clientv3.SortByKey, clientv3.SortAscend)}
+	if endKey != "" {
+		opts = append(opts, clientv3.WithRange(endKey))
+	} else {
+		opts = append(opts, clientv3.WithPrefix())
+	}
+	if limit > 0 {
+		opts = append(opts, clientv3.WithLimit(int64(limit)))
+	}
+	resp, err := b.client.Get(context.Background(), key, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	keys = make([]string, 0, len(resp.Kvs))
+	values = make([]string, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		keys = append(keys, string(kv.Key))
+		values = append(values, string(kv.Value))
+	}
+	return keys, values, nil
+}
+
+// Save implements RuleBackend.
+func (b *etcdClientRuleBackend) Save(key, value string) error {
+	_, err := b.client.Put(context.Background(), key, value)
+	return err
+}
+
+// Remove implements RuleBackend.
+func (b *etcdClientRuleBackend) Remove(key string) error {
+	_, err := b.client.Delete(context.Background(), key)
+	return err
+}
+
+// Watch implements RuleBackend.
+func (b *etcdClientRuleBackend) Watch(ctx context.Context, prefix string, fromRevision int64) (<-chan RuleEvent, error) {
+	return watchEtcdPrefix(ctx, b.client, prefix, fromRevision), nil
+}
+
+// watchEtcdPrefix streams RuleEvents translated from client's watch channel
+// on prefix, starting just after fromRevision when it's set. It stops and
+// closes the returned channel as soon as ctx is canceled; shared by
+// etcdRuleBackend and etcdClientRuleBackend since both watch the same way,
+// just from a differently-obtained *clientv3.Client.
+func watchEtcdPrefix(ctx context.Context, client *clientv3.Client, prefix string, fromRevision int64) <-chan RuleEvent {
+	out := make(chan RuleEvent, 16)
+	opts := []clientv3.OpOption{clientv3.WithPrefix()}
+	if fromRevision > 0 {
+		opts = append(opts, clientv3.WithRev(fromRevision+1))
+	}
+	watchChan := client.Watch(ctx, prefix, opts...)
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case resp, ok := <-watchChan:
+				if !ok {
+					return
+				}
+				for _, ev := range resp.Events {
+					re := RuleEvent{Key: string(ev.Kv.Key), Revision: ev.Kv.ModRevision}
+					if ev.Type == mvccpb.DELETE {
+						re.Type = RuleEventDelete
+					} else {
+						re.Type = RuleEventPut
+						re.Value = string(ev.Kv.Value)
+					}
+					select {
+					case out <- re:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+		}
+	}()
+	return out
+}
@@ -0,0 +1,170 @@
+// Copyright 2024 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package endpoint
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var rulesBucket = []byte("rules")
+
+// fileRuleBackendHistorySize bounds how many recent events a
+// fileRuleBackend keeps around so a Watch call with fromRevision > 0 can
+// replay what it missed instead of silently dropping it.
+const fileRuleBackendHistorySize = 1024
+
+// fileRuleBackend persists rules to a local BoltDB file. It is meant for
+// single-node test/dev clusters that don't want to run etcd or Consul.
+type fileRuleBackend struct {
+	db *bolt.DB
+
+	mu       sync.Mutex
+	revision int64
+	history  []RuleEvent
+	watchers []fileRuleWatcher
+}
+
+type fileRuleWatcher struct {
+	prefix string
+	ch     chan RuleEvent
+}
+
+func newFileRuleBackend(path string) (RuleBackend, error) {
+	db, err := bolt.Open(path, 0600, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(rulesBucket)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &fileRuleBackend{db: db}, nil
+}
+
+// Load implements RuleBackend.
+func (b *fileRuleBackend) Load(key string) (value string, err error) {
+	err = b.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(rulesBucket).Get([]byte(key))
+		if v != nil {
+			value = string(v)
+		}
+		return nil
+	})
+	return
+}
+
+// LoadRange implements RuleBackend, returning keys in [key, endKey) sorted
+// lexicographically, capped at limit (0 means unlimited).
+func (b *fileRuleBackend) LoadRange(key, endKey string, limit int) (keys, values []string, err error) {
+	err = b.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(rulesBucket).Cursor()
+		for k, v := c.Seek([]byte(key)); k != nil; k, v = c.Next() {
+			if endKey != "" && string(k) >= endKey {
+				break
+			}
+			keys = append(keys, string(k))
+			values = append(values, string(v))
+			if limit > 0 && len(keys) >= limit {
+				break
+			}
+		}
+		return nil
+	})
+	sort.Strings(keys)
+	return
+}
+
+// Save implements RuleBackend.
+func (b *fileRuleBackend) Save(key, value string) error {
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(rulesBucket).Put([]byte(key), []byte(value))
+	})
+	if err == nil {
+		b.notify(RuleEvent{Type: RuleEventPut, Key: key, Value: value})
+	}
+	return err
+}
+
+// Remove implements RuleBackend.
+func (b *fileRuleBackend) Remove(key string) error {
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(rulesBucket).Delete([]byte(key))
+	})
+	if err == nil {
+		b.notify(RuleEvent{Type: RuleEventDelete, Key: key})
+	}
+	return err
+}
+
+// Watch implements RuleBackend. Since BoltDB has no native watch primitive,
+// every Save/Remove is fanned out to the registered watcher channels; events
+// with a revision > fromRevision already in history are replayed first.
+// Only the last fileRuleBackendHistorySize events are kept, so older ones
+// are not replayed, and a live event is dropped for a watcher whose channel
+// buffer is full. The watcher is deregistered and ch is closed as soon as
+// ctx is canceled.
+func (b *fileRuleBackend) Watch(ctx context.Context, prefix string, fromRevision int64) (<-chan RuleEvent, error) {
+	ch := make(chan RuleEvent, 16+fileRuleBackendHistorySize)
+	b.mu.Lock()
+	for _, ev := range b.history {
+		if ev.Revision > fromRevision && strings.HasPrefix(ev.Key, prefix) {
+			ch <- ev
+		}
+	}
+	b.watchers = append(b.watchers, fileRuleWatcher{prefix: prefix, ch: ch})
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		for i, w := range b.watchers {
+			if w.ch == ch {
+				b.watchers = append(b.watchers[:i], b.watchers[i+1:]...)
+				break
+			}
+		}
+		close(ch)
+	}()
+	return ch, nil
+}
+
+func (b *fileRuleBackend) notify(ev RuleEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.revision++
+	ev.Revision = b.revision
+	b.history = append(b.history, ev)
+	if len(b.history) > fileRuleBackendHistorySize {
+		b.history = b.history[len(b.history)-fileRuleBackendHistorySize:]
+	}
+	for _, w := range b.watchers {
+		if !strings.HasPrefix(ev.Key, w.prefix) {
+			continue
+		}
+		select {
+		case w.ch <- ev:
+		default:
+		}
+	}
+}
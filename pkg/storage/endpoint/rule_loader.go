@@ -0,0 +1,339 @@
+// Copyright 2024 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package endpoint
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"sync"
+
+	"go.etcd.io/etcd/clientv3"
+)
+
+// loadRangeWorkerCounts tracks how many sub-ranges loadRangeByPrefix fans
+// out to concurrently, per *StorageEndpoint. It's keyed by instance rather
+// than a single package-level value because a process can run more than one
+// StorageEndpoint (e.g. tests, or a multi-cluster tool), and those shouldn't
+// share a worker count or race on setting it; the mutex makes concurrent
+// SetLoadRangeWorkers/loadRangeByPrefix calls from different goroutines
+// safe. A dedicated field on StorageEndpoint itself would be preferable,
+// but its definition lives outside this package's sparse slice of the
+// tree, so this map stands in for it.
+var (
+	loadRangeWorkerCountsMu sync.Mutex
+	loadRangeWorkerCounts   = map[*StorageEndpoint]int{}
+)
+
+// SetLoadRangeWorkers sets how many LoadRange calls se.loadRangeByPrefix may
+// run concurrently when walking a large key range (e.g. tens of thousands
+// of rules), trading etcd request fan-out for startup latency. n <= 1
+// restores the original serial-paging behavior. The default, for any se
+// that hasn't had this called, is 1 (serial), so existing deployments don't
+// change behavior unless an operator opts in.
+func SetLoadRangeWorkers(se *StorageEndpoint, n int) {
+	if n < 1 {
+		n = 1
+	}
+	loadRangeWorkerCountsMu.Lock()
+	defer loadRangeWorkerCountsMu.Unlock()
+	loadRangeWorkerCounts[se] = n
+}
+
+func loadRangeWorkersFor(se *StorageEndpoint) int {
+	loadRangeWorkerCountsMu.Lock()
+	defer loadRangeWorkerCountsMu.Unlock()
+	if n, ok := loadRangeWorkerCounts[se]; ok {
+		return n
+	}
+	return 1
+}
+
+// rangeSamplers holds the optional rangeSampler registered per
+// *StorageEndpoint via SetRangeSampler, keyed by instance for the same
+// reason as loadRangeWorkerCounts.
+var (
+	rangeSamplersMu sync.Mutex
+	rangeSamplers   = map[*StorageEndpoint]*rangeSampler{}
+)
+
+// rangeSampler issues the two etcd requests a concurrent load needs to fan
+// out evenly across a real (non-uniformly-distributed) rule-ID keyspace: a
+// single Count-only request to size the range, and a single KeysOnly
+// listing to sample actual keys at quantile boundaries. It's kept separate
+// from *StorageEndpoint, which has no raw etcd client to issue these with,
+// the same way NewEtcdRuleBackend takes an explicit client rather than
+// assuming StorageEndpoint exposes one.
+type rangeSampler struct {
+	client *clientv3.Client
+}
+
+// SetRangeSampler registers client as what se.loadRangeByPrefix uses to
+// count and quantile-sample a key range before splitting it across
+// concurrent workers (see SetLoadRangeWorkers). Without one, the split
+// falls back to linear byte interpolation between the range's start and
+// end keys (splitKeyRange), which assumes a roughly uniform keyspace and
+// can badly misbalance workers over arbitrary-length group/rule ID strings.
+func SetRangeSampler(se *StorageEndpoint, client *clientv3.Client) {
+	rangeSamplersMu.Lock()
+	defer rangeSamplersMu.Unlock()
+	rangeSamplers[se] = &rangeSampler{client: client}
+}
+
+func rangeSamplerFor(se *StorageEndpoint) *rangeSampler {
+	rangeSamplersMu.Lock()
+	defer rangeSamplersMu.Unlock()
+	return rangeSamplers[se]
+}
+
+// count issues a single Count-only etcd request over [startKey, endKey),
+// without fetching any keys or values.
+func (s *rangeSampler) count(ctx context.Context, startKey, endKey string) (int64, error) {
+	resp, err := s.client.Get(ctx, startKey, clientv3.WithRange(endKey), clientv3.WithCountOnly())
+	if err != nil {
+		return 0, err
+	}
+	return resp.Count, nil
+}
+
+// sampleBoundaries lists the actual keys in [startKey, endKey) (values
+// omitted, via WithKeysOnly) and returns the keys at quantile i/n for
+// i = 1..n-1, sized against the already-known count. Splitting on real
+// sampled keys, instead of assuming the keyspace is uniformly distributed
+// between startKey and endKey, keeps sub-ranges evenly loaded even when
+// rule IDs are arbitrary-length strings rather than sequential integers.
+func (s *rangeSampler) sampleBoundaries(ctx context.Context, startKey, endKey string, n int, count int64) ([]string, error) {
+	resp, err := s.client.Get(ctx, startKey, clientv3.WithRange(endKey), clientv3.WithKeysOnly(),
+		clientv3.WithSort(clientv3.SortByKey, clientv3.SortAscend))
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, len(resp.Kvs))
+	for i, kv := range resp.Kvs {
+		keys[i] = string(kv.Key)
+	}
+	return quantileBoundaries(keys, n, count), nil
+}
+
+// quantileBoundaries picks the keys in keys (sorted ascending, one entry per
+// key in [startKey, endKey)) sitting at quantile i/n for i = 1..n-1, sized
+// against count, the range's already-known total. It's split out of
+// sampleBoundaries so the selection math can be tested without a live etcd
+// client. A key already picked for the previous quantile is not repeated, so
+// when keys has fewer entries than n, fewer than n-1 boundaries come back.
+func quantileBoundaries(keys []string, n int, count int64) []string {
+	boundaries := make([]string, 0, n-1)
+	for i := 1; i < n; i++ {
+		idx := int64(i) * count / int64(n)
+		if idx >= int64(len(keys)) {
+			break
+		}
+		if len(boundaries) > 0 && boundaries[len(boundaries)-1] == keys[idx] {
+			continue
+		}
+		boundaries = append(boundaries, keys[idx])
+	}
+	return boundaries
+}
+
+type keyRange struct {
+	start, end string
+}
+
+// loadRangeByPrefix iterates all key-value pairs in the storage that has the prefix.
+func (se *StorageEndpoint) loadRangeByPrefix(prefix string, f func(k, v string)) error {
+	endKey := clientv3.GetPrefixRangeEnd(prefix)
+	workers := loadRangeWorkersFor(se)
+	if workers <= 1 {
+		return se.loadRangeByPrefixSerial(prefix, endKey, f)
+	}
+	subRanges, err := se.splitForConcurrentLoad(prefix, endKey, workers)
+	if err != nil {
+		return err
+	}
+	if len(subRanges) <= 1 {
+		return se.loadRangeByPrefixSerial(prefix, endKey, f)
+	}
+	return se.loadRangeByPrefixConcurrent(subRanges, prefix, f)
+}
+
+// splitForConcurrentLoad sizes [prefix, endKey) with a single Count-only
+// request and splits it into up to workers sub-ranges by sampling real keys
+// at quantile boundaries, using the rangeSampler registered for se via
+// SetRangeSampler. Without one registered, it falls back to splitKeyRange's
+// linear byte interpolation.
+func (se *StorageEndpoint) splitForConcurrentLoad(prefix, endKey string, workers int) ([]keyRange, error) {
+	sampler := rangeSamplerFor(se)
+	if sampler == nil {
+		return splitKeyRange(prefix, endKey, workers), nil
+	}
+	ctx := context.Background()
+	count, err := sampler.count(ctx, prefix, endKey)
+	if err != nil {
+		return nil, err
+	}
+	if count < int64(MinKVRangeLimit) {
+		return []keyRange{{start: prefix, end: endKey}}, nil
+	}
+	if int64(workers) > count {
+		workers = int(count)
+	}
+	boundaries, err := sampler.sampleBoundaries(ctx, prefix, endKey, workers, count)
+	if err != nil {
+		return nil, err
+	}
+	ranges := make([]keyRange, 0, len(boundaries)+1)
+	prev := prefix
+	for _, b := range boundaries {
+		if b <= prev || b >= endKey {
+			continue
+		}
+		ranges = append(ranges, keyRange{start: prev, end: b})
+		prev = b
+	}
+	ranges = append(ranges, keyRange{start: prev, end: endKey})
+	return ranges, nil
+}
+
+// loadRangeByPrefixSerial is the original MinKVRangeLimit-sized paging walk.
+func (se *StorageEndpoint) loadRangeByPrefixSerial(prefix, endKey string, f func(k, v string)) error {
+	nextKey := prefix
+	for {
+		keys, values, err := se.LoadRange(nextKey, endKey, MinKVRangeLimit)
+		if err != nil {
+			return err
+		}
+		for i := range keys {
+			f(strings.TrimPrefix(keys[i], prefix), values[i])
+		}
+		if len(keys) < MinKVRangeLimit {
+			return nil
+		}
+		nextKey = keys[len(keys)-1] + "\x00"
+	}
+}
+
+// loadRangeByPrefixConcurrent loads subRanges concurrently, bounded by a
+// worker pool sized to len(subRanges). Every sub-range is fully fetched
+// into memory before f is invoked for any of them, and only once all
+// sub-ranges have succeeded: unlike the in-order paging of
+// loadRangeByPrefixSerial, the concurrent fetches finish in whatever order
+// etcd answers them, so delivering to f as results arrived would mean a
+// failed sub-range could still leave an arbitrary, order-dependent subset
+// of the other sub-ranges delivered. Buffering everything first keeps the
+// error case all-or-nothing: on error, f is called for nothing at all.
+func (se *StorageEndpoint) loadRangeByPrefixConcurrent(subRanges []keyRange, prefix string, f func(k, v string)) error {
+	type page struct {
+		keys, values []string
+	}
+	pages := make([]page, len(subRanges))
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, len(subRanges))
+	errs := make(chan error, len(subRanges))
+	for i, r := range subRanges {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, r keyRange) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			var keys, values []string
+			nextKey := r.start
+			for {
+				ks, vs, err := se.LoadRange(nextKey, r.end, MinKVRangeLimit)
+				if err != nil {
+					errs <- err
+					return
+				}
+				keys = append(keys, ks...)
+				values = append(values, vs...)
+				if len(ks) < MinKVRangeLimit {
+					break
+				}
+				nextKey = ks[len(ks)-1] + "\x00"
+			}
+			pages[i] = page{keys: keys, values: values}
+		}(i, r)
+	}
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	for _, p := range pages {
+		for j := range p.keys {
+			f(strings.TrimPrefix(p.keys[j], prefix), p.values[j])
+		}
+	}
+	return nil
+}
+
+// splitKeyRange splits [start, end) into up to n ordered, non-overlapping
+// sub-ranges by interpolating boundary keys between start and end. It's the
+// fallback used when no rangeSampler is registered for a StorageEndpoint
+// (see SetRangeSampler): lacking any real key listing to sample quantiles
+// from, it approximates even fan-out by assuming rule keys are roughly
+// uniformly distributed between the range's bounds, which doesn't hold in
+// general for arbitrary-length group/rule ID strings.
+func splitKeyRange(start, end string, n int) []keyRange {
+	if n <= 1 || end == "" {
+		return []keyRange{{start: start, end: end}}
+	}
+	startBytes, endBytes := []byte(start), []byte(end)
+	ranges := make([]keyRange, 0, n)
+	prev := start
+	for i := 1; i < n; i++ {
+		boundary := interpolateKey(startBytes, endBytes, float64(i)/float64(n))
+		if boundary <= prev || boundary >= end {
+			continue
+		}
+		ranges = append(ranges, keyRange{start: prev, end: boundary})
+		prev = boundary
+	}
+	ranges = append(ranges, keyRange{start: prev, end: end})
+	return ranges
+}
+
+// interpolateKey returns the key frac of the way between a and b, treating
+// both as big-endian integers of the same (zero-padded) length.
+func interpolateKey(a, b []byte, frac float64) string {
+	length := len(a)
+	if len(b) > length {
+		length = len(b)
+	}
+	ai := new(big.Int).SetBytes(padKey(a, length))
+	bi := new(big.Int).SetBytes(padKey(b, length))
+	diff := new(big.Int).Sub(bi, ai)
+	const scale = 1 << 20
+	step := new(big.Int).Mul(diff, big.NewInt(int64(frac*scale)))
+	step.Div(step, big.NewInt(scale))
+	result := new(big.Int).Add(ai, step)
+	resultBytes := result.Bytes()
+	out := make([]byte, length)
+	copy(out[length-len(resultBytes):], resultBytes)
+	return string(out)
+}
+
+func padKey(b []byte, length int) []byte {
+	if len(b) >= length {
+		return b
+	}
+	out := make([]byte, length)
+	copy(out, b)
+	return out
+}
@@ -0,0 +1,75 @@
+// Copyright 2024 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package endpoint
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitKeyRangeOrderedAndNonOverlapping(t *testing.T) {
+	re := require.New(t)
+	ranges := splitKeyRange("rules/", "rules0", 8)
+	re.Equal("rules/", ranges[0].start)
+	re.Equal("rules0", ranges[len(ranges)-1].end)
+	for i := 1; i < len(ranges); i++ {
+		re.LessOrEqual(ranges[i-1].end, ranges[i].start)
+		re.Less(ranges[i].start, ranges[i].end)
+	}
+}
+
+func TestSplitKeyRangeSingleWorker(t *testing.T) {
+	re := require.New(t)
+	ranges := splitKeyRange("rules/", "rules0", 1)
+	re.Len(ranges, 1)
+	re.Equal("rules/", ranges[0].start)
+	re.Equal("rules0", ranges[0].end)
+}
+
+func TestQuantileBoundariesEvenSplit(t *testing.T) {
+	re := require.New(t)
+	keys := make([]string, 12)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("rules/%02d", i)
+	}
+	boundaries := quantileBoundaries(keys, 4, int64(len(keys)))
+	re.Equal([]string{"rules/03", "rules/06", "rules/09"}, boundaries)
+}
+
+func TestQuantileBoundariesFewerKeysThanWorkers(t *testing.T) {
+	re := require.New(t)
+	keys := []string{"rules/a", "rules/b"}
+	boundaries := quantileBoundaries(keys, 8, int64(len(keys)))
+	re.LessOrEqual(len(boundaries), len(keys))
+}
+
+// BenchmarkSplitKeyRange100k exercises only splitKeyRange's boundary
+// interpolation math (not the quantile sampling of quantileBoundaries) over
+// a range sized like 100k synthetic rules; it does not drive
+// loadRangeByPrefixConcurrent and so can't catch a regression in the actual
+// concurrent LoadRange fan-out (request batching, worker contention, etc).
+// That would need a benchmark built on a real *StorageEndpoint, e.g. one
+// backed by an embedded etcd server, which this package doesn't have a test
+// harness for.
+func BenchmarkSplitKeyRange100k(b *testing.B) {
+	start := "rules/"
+	end := fmt.Sprintf("rules/%020d", 100000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		splitKeyRange(start, end, 16)
+	}
+}
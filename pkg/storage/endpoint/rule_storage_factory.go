@@ -0,0 +1,167 @@
+// Copyright 2024 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package endpoint
+
+import (
+	"encoding/json"
+	"strings"
+
+	"go.etcd.io/etcd/clientv3"
+)
+
+// IsValidRuleBackendKind reports whether kind is one of the supported rule
+// storage backends, including the empty string (meaning "use the default").
+// The `[replication] rule-storage-backend` config key is validated against
+// this before PD ever tries to construct a backend for it.
+func IsValidRuleBackendKind(kind RuleBackendKind) bool {
+	switch kind {
+	case "", RuleBackendEtcd, RuleBackendFile, RuleBackendConsul:
+		return true
+	default:
+		return false
+	}
+}
+
+// NewRuleStorage returns the RuleStorage backed by the configured backend:
+// for "etcd" (the default, including ""), it returns se directly, so
+// existing deployments keep talking to etcd exactly as before; for "file"
+// or "consul" it constructs the matching RuleBackend from opts and wraps it
+// so LoadRule/SaveRule/... actually read and write through it, not just the
+// standalone pd-rule-migration tool.
+func NewRuleStorage(kind RuleBackendKind, se *StorageEndpoint, opts string) (RuleStorage, error) {
+	switch kind {
+	case "", RuleBackendEtcd:
+		return se, nil
+	default:
+		backend, err := NewRuleBackend(kind, opts)
+		if err != nil {
+			return nil, err
+		}
+		return NewRuleBackendStorage(backend), nil
+	}
+}
+
+// ruleBackendStorage implements RuleStorage generically over any
+// RuleBackend, so a RuleStorage backed by the file or Consul backend
+// behaves exactly like the etcd-backed StorageEndpoint from the caller's
+// point of view.
+type ruleBackendStorage struct {
+	backend RuleBackend
+}
+
+var _ RuleStorage = (*ruleBackendStorage)(nil)
+
+// NewRuleBackendStorage wraps backend as a RuleStorage.
+func NewRuleBackendStorage(backend RuleBackend) RuleStorage {
+	return &ruleBackendStorage{backend: backend}
+}
+
+// LoadRule implements RuleStorage.
+func (s *ruleBackendStorage) LoadRule(ruleKey string) (string, error) {
+	return s.backend.Load(ruleKeyPath(ruleKey))
+}
+
+// LoadRules implements RuleStorage.
+func (s *ruleBackendStorage) LoadRules(f func(k, v string)) error {
+	return loadRangeFromBackend(s.backend, rulesPath+"/", f)
+}
+
+// SaveRule implements RuleStorage.
+func (s *ruleBackendStorage) SaveRule(ruleKey string, rule interface{}) error {
+	data, err := json.Marshal(rule)
+	if err != nil {
+		return err
+	}
+	return s.backend.Save(ruleKeyPath(ruleKey), string(data))
+}
+
+// SaveRuleJSON implements RuleStorage.
+func (s *ruleBackendStorage) SaveRuleJSON(ruleKey, rule string) error {
+	return s.backend.Save(ruleKeyPath(ruleKey), rule)
+}
+
+// DeleteRule implements RuleStorage.
+func (s *ruleBackendStorage) DeleteRule(ruleKey string) error {
+	return s.backend.Remove(ruleKeyPath(ruleKey))
+}
+
+// LoadRuleGroups implements RuleStorage.
+func (s *ruleBackendStorage) LoadRuleGroups(f func(k, v string)) error {
+	return loadRangeFromBackend(s.backend, ruleGroupPath+"/", f)
+}
+
+// SaveRuleGroup implements RuleStorage.
+func (s *ruleBackendStorage) SaveRuleGroup(groupID string, group interface{}) error {
+	data, err := json.Marshal(group)
+	if err != nil {
+		return err
+	}
+	return s.backend.Save(ruleGroupIDPath(groupID), string(data))
+}
+
+// SaveRuleGroupJSON implements RuleStorage.
+func (s *ruleBackendStorage) SaveRuleGroupJSON(groupID, group string) error {
+	return s.backend.Save(ruleGroupIDPath(groupID), group)
+}
+
+// DeleteRuleGroup implements RuleStorage.
+func (s *ruleBackendStorage) DeleteRuleGroup(groupID string) error {
+	return s.backend.Remove(ruleGroupIDPath(groupID))
+}
+
+// LoadRegionRules implements RuleStorage.
+func (s *ruleBackendStorage) LoadRegionRules(f func(k, v string)) error {
+	return loadRangeFromBackend(s.backend, regionLabelPath+"/", f)
+}
+
+// SaveRegionRule implements RuleStorage.
+func (s *ruleBackendStorage) SaveRegionRule(ruleKey string, rule interface{}) error {
+	data, err := json.Marshal(rule)
+	if err != nil {
+		return err
+	}
+	return s.backend.Save(regionLabelKeyPath(ruleKey), string(data))
+}
+
+// SaveRegionRuleJSON implements RuleStorage.
+func (s *ruleBackendStorage) SaveRegionRuleJSON(ruleKey, rule string) error {
+	return s.backend.Save(regionLabelKeyPath(ruleKey), rule)
+}
+
+// DeleteRegionRule implements RuleStorage.
+func (s *ruleBackendStorage) DeleteRegionRule(ruleKey string) error {
+	return s.backend.Remove(regionLabelKeyPath(ruleKey))
+}
+
+// loadRangeFromBackend iterates all key-value pairs under prefix on
+// backend, mirroring StorageEndpoint.loadRangeByPrefixSerial but against
+// the generic RuleBackend interface instead of etcd directly.
+func loadRangeFromBackend(backend RuleBackend, prefix string, f func(k, v string)) error {
+	nextKey := prefix
+	endKey := clientv3.GetPrefixRangeEnd(prefix)
+	for {
+		keys, values, err := backend.LoadRange(nextKey, endKey, MinKVRangeLimit)
+		if err != nil {
+			return err
+		}
+		for i := range keys {
+			f(strings.TrimPrefix(keys[i], prefix), values[i])
+		}
+		if len(keys) < MinKVRangeLimit {
+			return nil
+		}
+		nextKey = keys[len(keys)-1] + "\x00"
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright 2024 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package window
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// PercentileEstimator tracks p50/p95/p99-style percentiles of the points of
+// a Window's buckets. It maintains a bounded t-digest that is merged on
+// each bucket rotation instead of sorting every point on every query, so
+// Quantile is O(centroids) and independent of the number of points ever
+// observed. The digest covers every bucket passed to Rotate since the
+// estimator was created: points are never removed from it, and points
+// still in buckets that have not been rotated are not included.
+type PercentileEstimator struct {
+	window *Window
+	digest *tDigest
+}
+
+// NewPercentileEstimator creates a PercentileEstimator over w. compression
+// bounds the number of centroids the digest keeps; higher values trade
+// memory for accuracy. A compression <= 0 uses a sensible default.
+func NewPercentileEstimator(w *Window, compression float64) *PercentileEstimator {
+	if compression <= 0 {
+		compression = 100
+	}
+	return &PercentileEstimator{window: w, digest: newTDigest(compression)}
+}
+
+// Rotate merges the points currently in the bucket at offset into the
+// digest. Callers should invoke this immediately before ResetBucket(offset)
+// so the digest absorbs the bucket's contribution before it is cleared.
+func (e *PercentileEstimator) Rotate(offset int) {
+	for _, v := range e.window.Bucket(offset).Points {
+		e.digest.add(v, 1)
+	}
+}
+
+// Quantile returns the estimated value at percentile p, where p is in (0, 1].
+func (e *PercentileEstimator) Quantile(p float64) float64 {
+	return e.digest.quantile(p)
+}
+
+// P50 returns the estimated median.
+func (e *PercentileEstimator) P50() float64 { return e.Quantile(0.50) }
+
+// P95 returns the estimated 95th percentile.
+func (e *PercentileEstimator) P95() float64 { return e.Quantile(0.95) }
+
+// P99 returns the estimated 99th percentile.
+func (e *PercentileEstimator) P99() float64 { return e.Quantile(0.99) }
+
+// EWMAEstimator tracks an exponentially weighted moving average of the
+// buckets of a Window, where each bucket's contribution is weighted by its
+// age. The weight is fixed when the bucket is passed to Observe and every
+// observation stays in the running sums. It is cheaper to maintain than a
+// percentile digest and is better suited to tracking a trending mean.
+type EWMAEstimator struct {
+	window   *Window
+	halfLife time.Duration
+	sumWV    float64
+	sumW     float64
+}
+
+// NewEWMAEstimator creates an EWMAEstimator over w with the given half-life:
+// a bucket's weight halves every halfLife it ages.
+func NewEWMAEstimator(w *Window, halfLife time.Duration) *EWMAEstimator {
+	return &EWMAEstimator{window: w, halfLife: halfLife}
+}
+
+// Observe folds a bucket's value, aged by age, into the running average.
+// Callers should invoke this on each bucket rotation (e.g. right before
+// ResetBucket), passing the age of the bucket being rotated out.
+func (e *EWMAEstimator) Observe(value float64, age time.Duration) {
+	weight := math.Exp(-math.Ln2 * age.Seconds() / e.halfLife.Seconds())
+	e.sumWV += weight * value
+	e.sumW += weight
+}
+
+// Value returns the current weighted average, or 0 if nothing has been
+// observed yet.
+func (e *EWMAEstimator) Value() float64 {
+	if e.sumW == 0 {
+		return 0
+	}
+	return e.sumWV / e.sumW
+}
+
+// centroid is a single cluster of a t-digest: a mean and the total weight
+// of points merged into it.
+type centroid struct {
+	mean   float64
+	weight float64
+}
+
+// tDigest is a bounded approximate-quantile sketch. Points are merged into
+// centroids so the number of centroids stays close to compression,
+// regardless of how many points are added.
+type tDigest struct {
+	compression float64
+	centroids   []centroid
+	count       float64
+}
+
+func newTDigest(compression float64) *tDigest {
+	return &tDigest{compression: compression}
+}
+
+func (d *tDigest) add(mean, weight float64) {
+	d.centroids = append(d.centroids, centroid{mean: mean, weight: weight})
+	d.count += weight
+	// Bound the centroid count so the query path stays O(compression)
+	// instead of growing with every point ever added.
+	if float64(len(d.centroids)) > d.compression*4 {
+		d.compress()
+	}
+}
+
+// compress sorts centroids by mean and greedily merges neighbors into
+// groups of about count/compression total weight each, so the result has
+// roughly `compression` centroids regardless of how many went in.
+func (d *tDigest) compress() {
+	if len(d.centroids) <= int(d.compression) {
+		return
+	}
+	sort.Slice(d.centroids, func(i, j int) bool { return d.centroids[i].mean < d.centroids[j].mean })
+	groupWeight := d.count / d.compression
+	if groupWeight <= 0 {
+		groupWeight = 1
+	}
+	merged := make([]centroid, 0, int(d.compression)+1)
+	cur := d.centroids[0]
+	for _, c := range d.centroids[1:] {
+		if cur.weight+c.weight <= groupWeight {
+			cur = centroid{
+				mean:   (cur.mean*cur.weight + c.mean*c.weight) / (cur.weight + c.weight),
+				weight: cur.weight + c.weight,
+			}
+			continue
+		}
+		merged = append(merged, cur)
+		cur = c
+	}
+	merged = append(merged, cur)
+	d.centroids = merged
+}
+
+// quantile returns the estimated value at percentile p in (0, 1].
+func (d *tDigest) quantile(p float64) float64 {
+	if len(d.centroids) == 0 {
+		return 0
+	}
+	d.compress()
+	sort.Slice(d.centroids, func(i, j int) bool { return d.centroids[i].mean < d.centroids[j].mean })
+	target := p * d.count
+	var cumulative float64
+	for _, c := range d.centroids {
+		cumulative += c.weight
+		if cumulative >= target {
+			return c.mean
+		}
+	}
+	return d.centroids[len(d.centroids)-1].mean
+}
@@ -0,0 +1,71 @@
+// The MIT License (MIT)
+// Copyright (c) 2022 go-kratos Project Authors.
+//
+// Copyright 2024 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package window
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPercentileEstimator(t *testing.T) {
+	re := require.New(t)
+	opts := Options{Size: 3}
+	w := NewWindow(opts)
+	for i := 1; i <= 100; i++ {
+		w.Append(0, float64(i))
+	}
+	est := NewPercentileEstimator(w, 100)
+	est.Rotate(0)
+	re.InDelta(50, est.P50(), 5)
+	re.InDelta(95, est.P95(), 5)
+	re.InDelta(99, est.P99(), 5)
+}
+
+// TestPercentileEstimatorBeyondCompressionThreshold feeds enough points to
+// force compress() to run more than once (compression*4 is the trigger in
+// add()), so a compress() that collapses everything down to one centroid
+// would make every percentile return the same flat value here.
+func TestPercentileEstimatorBeyondCompressionThreshold(t *testing.T) {
+	re := require.New(t)
+	opts := Options{Size: 1}
+	w := NewWindow(opts)
+	for i := 1; i <= 1000; i++ {
+		w.Append(0, float64(i))
+	}
+	est := NewPercentileEstimator(w, 100)
+	est.Rotate(0)
+	p50, p95, p99 := est.P50(), est.P95(), est.P99()
+	re.NotEqual(p50, p95)
+	re.NotEqual(p95, p99)
+	re.InDelta(500, p50, 50)
+	re.InDelta(950, p95, 50)
+	re.InDelta(990, p99, 50)
+}
+
+func TestEWMAEstimatorDecay(t *testing.T) {
+	re := require.New(t)
+	opts := Options{Size: 2}
+	w := NewWindow(opts)
+	est := NewEWMAEstimator(w, time.Second)
+	est.Observe(100, 0)
+	re.Equal(float64(100), est.Value())
+	est.Observe(0, time.Second)
+	re.InDelta(66.7, est.Value(), 1)
+}
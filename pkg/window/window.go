@@ -0,0 +1,97 @@
+// The MIT License (MIT)
+// Copyright (c) 2022 go-kratos Project Authors.
+//
+// Copyright 2023 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package window
+
+// Bucket holds the raw points collected during one window slot.
+type Bucket struct {
+	Points []float64
+}
+
+// Options configures a Window.
+type Options struct {
+	// Size is the number of buckets the window holds.
+	Size int
+}
+
+// Window represents a sliding window made up of a fixed number of buckets.
+// Callers rotate through buckets by index (e.g. timestamp % Size) and
+// Append/Add points into them.
+type Window struct {
+	buckets []Bucket
+	size    int
+}
+
+// NewWindow creates a Window with the given options.
+func NewWindow(opts Options) *Window {
+	buckets := make([]Bucket, opts.Size)
+	for i := range buckets {
+		buckets[i] = Bucket{Points: make([]float64, 0)}
+	}
+	return &Window{buckets: buckets, size: opts.Size}
+}
+
+// ResetWindow resets all buckets in the window.
+func (w *Window) ResetWindow() {
+	for i := range w.buckets {
+		w.resetBucket(i)
+	}
+}
+
+// ResetBucket resets the bucket at offset.
+func (w *Window) ResetBucket(offset int) {
+	w.resetBucket(offset % w.size)
+}
+
+// ResetBuckets resets the count buckets starting at offset.
+func (w *Window) ResetBuckets(offset int, count int) {
+	for i := 0; i < count; i++ {
+		w.ResetBucket(offset + i)
+	}
+}
+
+func (w *Window) resetBucket(offset int) {
+	w.buckets[offset].Points = w.buckets[offset].Points[:0]
+}
+
+// Append appends val to the bucket at offset.
+func (w *Window) Append(offset int, val float64) {
+	offset %= w.size
+	w.buckets[offset].Points = append(w.buckets[offset].Points, val)
+}
+
+// Add adds val to the last point of the bucket at offset, or appends a new
+// point if the bucket is empty.
+func (w *Window) Add(offset int, val float64) {
+	offset %= w.size
+	points := w.buckets[offset].Points
+	if len(points) == 0 {
+		w.Append(offset, val)
+		return
+	}
+	points[len(points)-1] += val
+}
+
+// Bucket returns the bucket at offset.
+func (w *Window) Bucket(offset int) Bucket {
+	return w.buckets[offset%w.size]
+}
+
+// Size returns the number of buckets in the window.
+func (w *Window) Size() int {
+	return w.size
+}
@@ -0,0 +1,148 @@
+// Copyright 2024 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/tikv/pd/pkg/schedule/placement"
+	"github.com/tikv/pd/pkg/storage/endpoint"
+)
+
+// ruleWatchEventKind mirrors client/http's RuleEventKind so the frames this
+// handler writes decode cleanly on the other end.
+type ruleWatchEventKind string
+
+const (
+	ruleWatchEventUpdate ruleWatchEventKind = "update"
+	ruleWatchEventDelete ruleWatchEventKind = "delete"
+)
+
+// ruleWatchEvent is the wire shape consumed by client/http's
+// WatchPlacementRules/WatchRegionLabelRules: {type, key, rule|label_rule,
+// revision}. It intentionally does not reuse endpoint.RuleEvent, which is
+// an internal storage-layer type keyed by raw JSON string, not by the
+// parsed rule object the client expects. Key identifies which rule changed
+// (the rule/group/label-rule ID, with the watch's key prefix stripped) and
+// is always set, including on delete, where Rule/LabelRule are nil: it's
+// the only way a consumer mirroring PD's rules learns which one was
+// removed.
+type ruleWatchEvent struct {
+	Type      ruleWatchEventKind `json:"type"`
+	Key       string             `json:"key"`
+	Rule      *placement.Rule    `json:"rule,omitempty"`
+	LabelRule json.RawMessage    `json:"label_rule,omitempty"`
+	Revision  int64              `json:"revision"`
+}
+
+// ruleWatchHandler streams incremental rule changes as Server-Sent Events,
+// so callers like WatchPlacementRules/WatchRegionLabelRules don't need to
+// poll the full rule set. It reuses the RuleBackend.Watch channel that
+// already backs out-of-band rule edit notifications.
+type ruleWatchHandler struct {
+	backend endpoint.RuleBackend
+	prefix  string
+	// asLabelRule selects which field of ruleWatchEvent the backend's raw
+	// JSON value is attached to: placement.Rule for rules, or the raw label
+	// rule payload for region label rules.
+	asLabelRule bool
+}
+
+func newRuleWatchHandler(backend endpoint.RuleBackend, prefix string, asLabelRule bool) *ruleWatchHandler {
+	return &ruleWatchHandler{backend: backend, prefix: prefix, asLabelRule: asLabelRule}
+}
+
+// RegisterRuleWatchRoutes mounts the placement-rule and region-label-rule
+// watch endpoints backed by rulesBackend/labelsBackend. It must be called
+// from the server's route setup, alongside the rest of the `/pd/api/v1`
+// routes, once the corresponding RuleBackends have been constructed.
+func RegisterRuleWatchRoutes(r *mux.Router, rulesBackend, labelsBackend endpoint.RuleBackend, rulesPrefix, labelsPrefix string) {
+	r.Handle("/pd/api/v1/config/rules/watch", newRuleWatchHandler(rulesBackend, rulesPrefix, false)).Methods(http.MethodGet)
+	r.Handle("/pd/api/v1/config/region-label/rules/watch", newRuleWatchHandler(labelsBackend, labelsPrefix, true)).Methods(http.MethodGet)
+}
+
+// ServeHTTP writes one `data: <json event>` SSE frame per incremental
+// change, flushing after each one so clients observe changes as they occur.
+// The request's context is passed to Watch, so the backend's watch
+// goroutine stops as soon as the client disconnects instead of leaking for
+// the life of the process.
+func (h *ruleWatchHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	var fromRevision int64
+	if v := r.URL.Query().Get("from_revision"); v != "" {
+		parsed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			http.Error(w, "invalid from_revision", http.StatusBadRequest)
+			return
+		}
+		fromRevision = parsed
+	}
+	events, err := h.backend.Watch(r.Context(), h.prefix, fromRevision)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case ev, ok := <-events:
+			if !ok {
+				return
+			}
+			payload, err := json.Marshal(h.translate(ev))
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "data: %s\n\n", payload)
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// translate converts a storage-layer endpoint.RuleEvent into the
+// client-facing wire shape.
+func (h *ruleWatchHandler) translate(ev endpoint.RuleEvent) ruleWatchEvent {
+	out := ruleWatchEvent{Revision: ev.Revision, Key: strings.TrimPrefix(ev.Key, h.prefix)}
+	if ev.Type == endpoint.RuleEventDelete {
+		out.Type = ruleWatchEventDelete
+		return out
+	}
+	out.Type = ruleWatchEventUpdate
+	if h.asLabelRule {
+		out.LabelRule = json.RawMessage(ev.Value)
+		return out
+	}
+	var rule placement.Rule
+	if err := json.Unmarshal([]byte(ev.Value), &rule); err == nil {
+		out.Rule = &rule
+	}
+	return out
+}
@@ -0,0 +1,75 @@
+// Copyright 2024 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"github.com/pingcap/errors"
+	"github.com/tikv/pd/pkg/storage/endpoint"
+)
+
+// DefaultRuleStorageBackend is used when [replication] rule-storage-backend
+// is left unset, preserving the existing etcd-backed behavior.
+const DefaultRuleStorageBackend = "etcd"
+
+// ValidateRuleStorageBackend checks that backend is one of the supported
+// rule storage backends: "etcd", "file" or "consul"; the empty string,
+// meaning DefaultRuleStorageBackend, is accepted as well. It defers to
+// endpoint.IsValidRuleBackendKind, the single source of truth for which
+// RuleBackendKind values endpoint.NewRuleStorage knows how to construct, so
+// this can't drift out of sync with the backends that actually exist.
+func ValidateRuleStorageBackend(backend string) error {
+	if !endpoint.IsValidRuleBackendKind(endpoint.RuleBackendKind(backend)) {
+		return errors.Errorf("unknown rule-storage-backend %q", backend)
+	}
+	return nil
+}
+
+// ReplicationConfig holds the rule-storage-backend portion of the
+// `[replication]` config section. The rest of `[replication]`
+// (max-replicas, location-labels, ...) belongs to server/config.Config,
+// which isn't part of this checkout; embed this into it under the
+// `replication` key once it is, so `rule-storage-backend`/
+// `rule-storage-backend-opts` load the same way as the rest of the
+// section.
+type ReplicationConfig struct {
+	// RuleStorageBackend selects the endpoint.RuleBackendKind placement
+	// rules and region label rules are persisted to. Empty means
+	// DefaultRuleStorageBackend.
+	RuleStorageBackend string `toml:"rule-storage-backend" json:"rule-storage-backend"`
+	// RuleStorageBackendOpts is backend-specific: the BoltDB database file
+	// path for "file", a Consul address for "consul", ignored for "etcd".
+	RuleStorageBackendOpts string `toml:"rule-storage-backend-opts" json:"rule-storage-backend-opts"`
+}
+
+// Adjust fills in DefaultRuleStorageBackend if RuleStorageBackend is unset
+// and validates the result. Call this alongside the rest of
+// `[replication]`'s adjustment during config load, before the server
+// constructs its RuleStorage.
+func (c *ReplicationConfig) Adjust() error {
+	if c.RuleStorageBackend == "" {
+		c.RuleStorageBackend = DefaultRuleStorageBackend
+	}
+	return ValidateRuleStorageBackend(c.RuleStorageBackend)
+}
+
+// NewRuleStorage builds the endpoint.RuleStorage this config selects,
+// wrapping se (the existing etcd-backed StorageEndpoint) when
+// RuleStorageBackend is "etcd" (the default) and constructing a file/Consul
+// RuleBackend otherwise. Call this once during server startup, after
+// Adjust, and use the result everywhere rule storage is needed instead of
+// se directly.
+func (c *ReplicationConfig) NewRuleStorage(se *endpoint.StorageEndpoint) (endpoint.RuleStorage, error) {
+	return endpoint.NewRuleStorage(endpoint.RuleBackendKind(c.RuleStorageBackend), se, c.RuleStorageBackendOpts)
+}
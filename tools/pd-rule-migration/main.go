@@ -0,0 +1,92 @@
+// Copyright 2024 TiKV Project Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command pd-rule-migration copies all rules, rule groups and region label
+// rules between two RuleBackends, e.g. to move a cluster from etcd to a
+// file or Consul rule storage backend without downtime.
+package main
+
+import (
+	"flag"
+	"log"
+	"strings"
+	"time"
+
+	"go.etcd.io/etcd/clientv3"
+
+	"github.com/tikv/pd/pkg/storage/endpoint"
+)
+
+var (
+	fromKind = flag.String("from-kind", "", "source rule backend: etcd|file|consul")
+	fromOpts = flag.String("from-opts", "", "source backend options: comma-separated etcd endpoints, a file path, or a consul address")
+	toKind   = flag.String("to-kind", "", "destination rule backend: etcd|file|consul")
+	toOpts   = flag.String("to-opts", "", "destination backend options: comma-separated etcd endpoints, a file path, or a consul address")
+	prefix   = flag.String("prefix", "", "only migrate keys under this prefix")
+
+	etcdDialTimeout = flag.Duration("etcd-dial-timeout", 5*time.Second, "dial timeout used when -from-kind/-to-kind is etcd")
+)
+
+func main() {
+	flag.Parse()
+	from, closeFrom, err := openBackend(endpoint.RuleBackendKind(*fromKind), *fromOpts)
+	if err != nil {
+		log.Fatalf("open source backend: %v", err)
+	}
+	defer closeFrom()
+	to, closeTo, err := openBackend(endpoint.RuleBackendKind(*toKind), *toOpts)
+	if err != nil {
+		log.Fatalf("open destination backend: %v", err)
+	}
+	defer closeTo()
+
+	// Bound the range to keys under prefix: with an empty endKey, the file
+	// backend returns every key from prefix onward, not only those under it.
+	endKey := ""
+	if *prefix != "" {
+		endKey = clientv3.GetPrefixRangeEnd(*prefix)
+	}
+	keys, values, err := from.LoadRange(*prefix, endKey, 0)
+	if err != nil {
+		log.Fatalf("load source rules: %v", err)
+	}
+	for i, key := range keys {
+		if err := to.Save(key, values[i]); err != nil {
+			log.Fatalf("save rule %q to destination: %v", key, err)
+		}
+	}
+	log.Printf("migrated %d rules from %s to %s", len(keys), *fromKind, *toKind)
+}
+
+// openBackend opens the RuleBackend named by kind. endpoint.NewRuleBackend
+// refuses to construct an etcd-backed one (it wraps an existing
+// StorageEndpoint instead, which this standalone tool doesn't have), but
+// etcd is the default backend for every existing deployment and therefore
+// the common source or destination for a migration, so handle it here by
+// dialing opts as etcd endpoints directly. The returned close func releases
+// the etcd client opened for that case; it's a no-op for file/Consul.
+func openBackend(kind endpoint.RuleBackendKind, opts string) (endpoint.RuleBackend, func(), error) {
+	if kind != endpoint.RuleBackendEtcd {
+		backend, err := endpoint.NewRuleBackend(kind, opts)
+		return backend, func() {}, err
+	}
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints:   strings.Split(opts, ","),
+		DialTimeout: *etcdDialTimeout,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return endpoint.NewEtcdRuleBackendFromClient(client), func() { client.Close() }, nil
+}